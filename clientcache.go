@@ -0,0 +1,199 @@
+package main
+
+import (
+	"container/list"
+	"context"
+	"fmt"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// defaultClientCacheSize and defaultClientCacheTTL are used when
+// ?clientCache=true is set without an explicit size/TTL override.
+const (
+	defaultClientCacheSize = 10000
+	defaultClientCacheTTL  = 30 * time.Second
+)
+
+// clientCacheInvalidateChannel is the pub/sub channel Redis delivers
+// invalidation notifications to for a connection that enabled CLIENT
+// TRACKING with REDIRECT pointed at itself.
+const clientCacheInvalidateChannel = "__redis__:invalidate"
+
+// clientCacheEntry is one value held by clientCache.
+type clientCacheEntry struct {
+	key       string
+	value     string
+	expiresAt time.Time
+}
+
+// clientCache is a bounded, TTL-capped, in-process cache of recently read
+// values. It exists to give hot Get calls sub-microsecond, network-free
+// latency; every entry still carries a hard TTL so a missed or delayed
+// server-side invalidation can never leave it stale indefinitely. Coherence
+// with Redis is otherwise maintained by evicting entries named in
+// invalidation pushes; see startClientCacheSubscriber.
+type clientCache struct {
+	mu       sync.Mutex
+	capacity int
+	ttl      time.Duration
+	clock    clock
+	order    *list.List
+	items    map[string]*list.Element
+}
+
+// newClientCache returns an empty cache holding at most capacity entries,
+// each valid for ttl after it was last written. Entries are timestamped
+// using clk, so tests can substitute a fake clock to exercise expiry
+// without sleeping in real time.
+func newClientCache(capacity int, ttl time.Duration, clk clock) *clientCache {
+	return &clientCache{
+		capacity: capacity,
+		ttl:      ttl,
+		clock:    clk,
+		order:    list.New(),
+		items:    make(map[string]*list.Element),
+	}
+}
+
+// get returns the cached value for key, if present and not yet expired.
+func (c *clientCache) get(key string) (string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	el, ok := c.items[key]
+	if !ok {
+		return "", false
+	}
+	entry := el.Value.(*clientCacheEntry)
+	if c.clock.Now().After(entry.expiresAt) {
+		c.order.Remove(el)
+		delete(c.items, key)
+		return "", false
+	}
+	c.order.MoveToFront(el)
+	return entry.value, true
+}
+
+// set stores value for key, refreshing its TTL and recency, and evicts the
+// least recently used entry if doing so pushed the cache over capacity.
+func (c *clientCache) set(key, value string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	expiresAt := c.clock.Now().Add(c.ttl)
+	if el, ok := c.items[key]; ok {
+		entry := el.Value.(*clientCacheEntry)
+		entry.value = value
+		entry.expiresAt = expiresAt
+		c.order.MoveToFront(el)
+		return
+	}
+
+	el := c.order.PushFront(&clientCacheEntry{key: key, value: value, expiresAt: expiresAt})
+	c.items[key] = el
+
+	if c.order.Len() > c.capacity {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.items, oldest.Value.(*clientCacheEntry).key)
+	}
+}
+
+// evict removes key from the cache, if present.
+func (c *clientCache) evict(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if el, ok := c.items[key]; ok {
+		c.order.Remove(el)
+		delete(c.items, key)
+	}
+}
+
+// flush empties the cache. Redis sends a nil-payload invalidation message to
+// every tracking client when its server-side tracking table overflows,
+// since it can no longer tell us precisely which keys changed.
+func (c *clientCache) flush() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.order.Init()
+	c.items = make(map[string]*list.Element)
+}
+
+// trackingRedirectTarget coordinates CLIENT TRACKING ... REDIRECT across
+// every connection a tracking-enabled client opens. Redis requires the
+// REDIRECT target to be one specific connection that has SUBSCRIBEd to
+// clientCacheInvalidateChannel; redirecting a connection to itself (which
+// never subscribes to anything) silently drops every invalidation push.
+// assign.Do fires for exactly one connection - the first one dialed, which
+// InitConnection arranges to be the one startClientCacheSubscriber opens -
+// and every other connection reads the id it captured.
+type trackingRedirectTarget struct {
+	assign sync.Once
+	id     atomic.Int64
+}
+
+// clientTrackingOnConnect returns a go-redis OnConnect hook that enables
+// server-assisted invalidation tracking on every new connection the client
+// opens, except the one connection claimed by target as the REDIRECT
+// destination; that connection is left untouched so startClientCacheSubscriber
+// can turn it into a plain subscriber of clientCacheInvalidateChannel. It uses
+// BCAST mode scoped to prefix, so a connection doesn't have to be the one that
+// read a key to be told it changed.
+func clientTrackingOnConnect(prefix string, target *trackingRedirectTarget) func(ctx context.Context, cn *redis.Conn) error {
+	return func(ctx context.Context, cn *redis.Conn) error {
+		id, err := cn.ClientID(ctx).Result()
+		if err != nil {
+			return fmt.Errorf("failed to read client id for cache tracking: %w", err)
+		}
+
+		claimed := false
+		target.assign.Do(func() {
+			target.id.Store(id)
+			claimed = true
+		})
+		if claimed {
+			return nil
+		}
+
+		args := []interface{}{"client", "tracking", "on", "redirect", strconv.FormatInt(target.id.Load(), 10), "bcast"}
+		if prefix != "" {
+			args = append(args, "prefix", prefix)
+		}
+		if err := cn.Do(ctx, args...).Err(); err != nil {
+			return fmt.Errorf("failed to enable client-side cache tracking: %w", err)
+		}
+		return nil
+	}
+}
+
+// startClientCacheSubscriber opens the dedicated pub/sub connection subscribed
+// to clientCacheInvalidateChannel and evicts the corresponding entries from
+// cache as invalidation notifications arrive, keeping it coherent with Redis.
+// Callers must open it before any other call reaches client, so its dial is
+// the one clientTrackingOnConnect's target.assign claims as the REDIRECT
+// destination. The returned PubSub must be closed (see redisCachePlugin.Close)
+// to stop the background goroutine it starts.
+func startClientCacheSubscriber(ctx context.Context, client redis.UniversalClient, cache *clientCache) *redis.PubSub {
+	sub := client.Subscribe(ctx, clientCacheInvalidateChannel)
+
+	go func() {
+		for msg := range sub.Channel() {
+			if len(msg.PayloadSlice) == 0 {
+				cache.flush()
+				continue
+			}
+			for _, key := range msg.PayloadSlice {
+				cache.evict(key)
+			}
+		}
+	}()
+
+	return sub
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeClock is a clock whose Now() is advanced explicitly, letting tests
+// exercise TTL expiry deterministically instead of sleeping in real time.
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time { return c.now }
+
+func (c *fakeClock) advance(d time.Duration) { c.now = c.now.Add(d) }
+
+func TestClientCache_GetMiss(t *testing.T) {
+	c := newClientCache(10, time.Minute, realClock{})
+
+	val, ok := c.get("missing")
+	assert.False(t, ok)
+	assert.Empty(t, val)
+}
+
+func TestClientCache_SetGet(t *testing.T) {
+	c := newClientCache(10, time.Minute, realClock{})
+
+	c.set("key", "value")
+	val, ok := c.get("key")
+	assert.True(t, ok)
+	assert.Equal(t, "value", val)
+}
+
+func TestClientCache_Expiry(t *testing.T) {
+	clk := &fakeClock{now: time.Unix(0, 0)}
+	c := newClientCache(10, time.Millisecond, clk)
+
+	c.set("key", "value")
+	clk.advance(5 * time.Millisecond)
+
+	val, ok := c.get("key")
+	assert.False(t, ok)
+	assert.Empty(t, val)
+}
+
+func TestClientCache_EvictsLeastRecentlyUsedOverCapacity(t *testing.T) {
+	c := newClientCache(2, time.Minute, realClock{})
+
+	c.set("a", "1")
+	c.set("b", "2")
+	c.get("a") // touch "a" so "b" becomes the least recently used
+	c.set("c", "3")
+
+	_, ok := c.get("b")
+	assert.False(t, ok, "least recently used entry should have been evicted")
+
+	val, ok := c.get("a")
+	assert.True(t, ok)
+	assert.Equal(t, "1", val)
+
+	val, ok = c.get("c")
+	assert.True(t, ok)
+	assert.Equal(t, "3", val)
+}
+
+func TestClientCache_Evict(t *testing.T) {
+	c := newClientCache(10, time.Minute, realClock{})
+
+	c.set("key", "value")
+	c.evict("key")
+
+	_, ok := c.get("key")
+	assert.False(t, ok)
+}
+
+func TestClientCache_Flush(t *testing.T) {
+	c := newClientCache(10, time.Minute, realClock{})
+
+	c.set("a", "1")
+	c.set("b", "2")
+	c.flush()
+
+	_, ok := c.get("a")
+	assert.False(t, ok)
+	_, ok = c.get("b")
+	assert.False(t, ok)
+}
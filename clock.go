@@ -0,0 +1,15 @@
+package main
+
+import "time"
+
+// clock abstracts the current time so tests can drive TTL-dependent
+// behavior (e.g. clientCache expiry) deterministically instead of sleeping
+// in real time.
+type clock interface {
+	Now() time.Time
+}
+
+// realClock is the clock used outside of tests.
+type realClock struct{}
+
+func (realClock) Now() time.Time { return time.Now() }
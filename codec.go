@@ -0,0 +1,347 @@
+package main
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/gob"
+	"fmt"
+	"io"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/golang/snappy"
+	"github.com/klauspost/compress/zstd"
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+// valueMagicByte prefixes every value framed by packValue so Get can tell a
+// codec-aware entry from a legacy raw one and knows how to reverse it.
+const valueMagicByte byte = 0xEA
+
+// defaultCompressMinBytes is used when ?compressMinBytes= is not given,
+// so selecting a compressing codec doesn't pay compression overhead on
+// values too small to benefit from it.
+const defaultCompressMinBytes = 1024
+
+// Codec identifiers, stored as the third header byte of a framed value.
+const (
+	codecRaw byte = iota
+	codecGzip
+	codecSnappy
+	codecZstd
+)
+
+// Encoding identifiers, stored as the second header byte of a framed value.
+const (
+	encodingRaw byte = iota
+	encodingGob
+	encodingMsgpack
+)
+
+var codecNames = map[string]byte{
+	"raw":    codecRaw,
+	"gzip":   codecGzip,
+	"snappy": codecSnappy,
+	"zstd":   codecZstd,
+}
+
+var encodingNames = map[string]byte{
+	"raw":     encodingRaw,
+	"gob":     encodingGob,
+	"msgpack": encodingMsgpack,
+}
+
+// pluginQueryOptions holds the settings this plugin layers on top of the
+// underlying go-redis client. They come from URI query parameters that
+// redis.ParseURL / ClusterOptions / FailoverOptions don't know about, so
+// extractPluginParams removes them from the query before it reaches go-redis.
+type pluginQueryOptions struct {
+	codecID          byte
+	encodingID       byte
+	compressMinBytes int
+
+	// prefix is prepended to every cache key; see redisCachePlugin.prefix.
+	prefix string
+
+	// db selects the logical database to use; dbSet distinguishes "not
+	// given" from "given as 0", so an explicit ?db=0 overrides a database
+	// chosen in a redis:// URI's path while an absent ?db= leaves it alone.
+	db    int
+	dbSet bool
+
+	// clientCache* configure the optional in-process cache layer; see
+	// clientcache.go. clientCacheSize and clientCacheTTL are only
+	// meaningful when clientCache is true.
+	clientCache     bool
+	clientCacheSize int
+	clientCacheTTL  time.Duration
+
+	// metrics and tracing enable the optional observability hooks; see
+	// metrics.go and tracing.go.
+	metrics bool
+	tracing bool
+
+	// opTimeout, if nonzero, bounds every Redis call this plugin issues via
+	// context.WithTimeout, so a slow upstream fails the calling request
+	// instead of piling up on the connection pool; see
+	// redisCachePlugin.withOpTimeout.
+	opTimeout time.Duration
+}
+
+// extractPluginParams parses and removes this plugin's own query parameters
+// from q, returning their values. Unknown values for codec/encoding are
+// rejected; everything else in q is left untouched for the caller to pass on.
+func extractPluginParams(q url.Values) (pluginQueryOptions, error) {
+	var opts pluginQueryOptions
+	var err error
+
+	if opts.codecID, err = codecByName(q.Get("codec")); err != nil {
+		return opts, err
+	}
+	q.Del("codec")
+
+	if opts.encodingID, err = encodingByName(q.Get("encoding")); err != nil {
+		return opts, err
+	}
+	q.Del("encoding")
+
+	opts.compressMinBytes = defaultCompressMinBytes
+	if v := q.Get("compressMinBytes"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return opts, fmt.Errorf("invalid compressMinBytes value %q: %w", v, err)
+		}
+		opts.compressMinBytes = n
+	}
+	q.Del("compressMinBytes")
+
+	opts.prefix = q.Get("prefix")
+	q.Del("prefix")
+
+	if v := q.Get("db"); v != "" {
+		db, err := strconv.Atoi(v)
+		if err != nil {
+			return opts, fmt.Errorf("invalid db value %q: %w", v, err)
+		}
+		opts.db = db
+		opts.dbSet = true
+	}
+	q.Del("db")
+
+	if v := q.Get("clientCache"); v != "" {
+		clientCache, err := strconv.ParseBool(v)
+		if err != nil {
+			return opts, fmt.Errorf("invalid clientCache value %q: %w", v, err)
+		}
+		opts.clientCache = clientCache
+	}
+	q.Del("clientCache")
+
+	opts.clientCacheSize = defaultClientCacheSize
+	if v := q.Get("clientCacheSize"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return opts, fmt.Errorf("invalid clientCacheSize value %q: %w", v, err)
+		}
+		opts.clientCacheSize = n
+	}
+	q.Del("clientCacheSize")
+
+	opts.clientCacheTTL = defaultClientCacheTTL
+	if v := q.Get("clientCacheTTL"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			return opts, fmt.Errorf("invalid clientCacheTTL value %q: %w", v, err)
+		}
+		opts.clientCacheTTL = d
+	}
+	q.Del("clientCacheTTL")
+
+	if opts.metrics, err = parseBoolParam(q, "metrics"); err != nil {
+		return opts, err
+	}
+	q.Del("metrics")
+
+	if opts.tracing, err = parseBoolParam(q, "tracing"); err != nil {
+		return opts, err
+	}
+	q.Del("tracing")
+
+	if v := q.Get("opTimeout"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			return opts, fmt.Errorf("invalid opTimeout value %q: %w", v, err)
+		}
+		opts.opTimeout = d
+	}
+	q.Del("opTimeout")
+
+	return opts, nil
+}
+
+// codecByName resolves a `?codec=` query value to its identifier, defaulting
+// to codecRaw when name is empty.
+func codecByName(name string) (byte, error) {
+	if name == "" {
+		return codecRaw, nil
+	}
+	id, ok := codecNames[name]
+	if !ok {
+		return 0, fmt.Errorf("unknown codec %q: must be one of raw, gzip, snappy, zstd", name)
+	}
+	return id, nil
+}
+
+// encodingByName resolves a `?encoding=` query value to its identifier,
+// defaulting to encodingRaw when name is empty.
+func encodingByName(name string) (byte, error) {
+	if name == "" {
+		return encodingRaw, nil
+	}
+	id, ok := encodingNames[name]
+	if !ok {
+		return 0, fmt.Errorf("unknown encoding %q: must be one of raw, gob, msgpack", name)
+	}
+	return id, nil
+}
+
+// packValue frames a value for storage: it encodes, optionally compresses
+// (skipped when the encoded size is below compressMinBytes), and prefixes
+// the result with a magic byte plus the encoding/codec identifiers so a
+// later Get can reverse the process regardless of the settings in effect
+// at that time.
+func packValue(encodingID, codecID byte, compressMinBytes int, value string) (string, error) {
+	encoded, err := encodeValue(encodingID, value)
+	if err != nil {
+		return "", err
+	}
+
+	effectiveCodec := codecID
+	if len(encoded) < compressMinBytes {
+		effectiveCodec = codecRaw
+	}
+
+	compressed, err := compressValue(effectiveCodec, encoded)
+	if err != nil {
+		return "", err
+	}
+
+	header := []byte{valueMagicByte, encodingID, effectiveCodec}
+	return string(append(header, compressed...)), nil
+}
+
+// unpackValue reverses packValue. A value without the magic-byte header is
+// assumed to predate codec support and is returned unchanged, so a Redis
+// instance can hold a mix of legacy and framed entries during a rollout.
+func unpackValue(data string) (string, error) {
+	if len(data) < 3 || data[0] != valueMagicByte {
+		return data, nil
+	}
+
+	encodingID, codecID := data[1], data[2]
+	decompressed, err := decompressValue(codecID, []byte(data[3:]))
+	if err != nil {
+		return "", err
+	}
+	return decodeValue(encodingID, decompressed)
+}
+
+// encodeValue serializes value according to encodingID, prior to compression.
+func encodeValue(encodingID byte, value string) ([]byte, error) {
+	switch encodingID {
+	case encodingRaw:
+		return []byte(value), nil
+	case encodingGob:
+		var buf bytes.Buffer
+		if err := gob.NewEncoder(&buf).Encode(value); err != nil {
+			return nil, fmt.Errorf("gob encode: %w", err)
+		}
+		return buf.Bytes(), nil
+	case encodingMsgpack:
+		b, err := msgpack.Marshal(value)
+		if err != nil {
+			return nil, fmt.Errorf("msgpack encode: %w", err)
+		}
+		return b, nil
+	default:
+		return nil, fmt.Errorf("unknown encoding id %d", encodingID)
+	}
+}
+
+// decodeValue reverses encodeValue.
+func decodeValue(encodingID byte, data []byte) (string, error) {
+	switch encodingID {
+	case encodingRaw:
+		return string(data), nil
+	case encodingGob:
+		var value string
+		if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&value); err != nil {
+			return "", fmt.Errorf("gob decode: %w", err)
+		}
+		return value, nil
+	case encodingMsgpack:
+		var value string
+		if err := msgpack.Unmarshal(data, &value); err != nil {
+			return "", fmt.Errorf("msgpack decode: %w", err)
+		}
+		return value, nil
+	default:
+		return "", fmt.Errorf("unknown encoding id %d", encodingID)
+	}
+}
+
+// compressValue applies the compression codec identified by codecID.
+func compressValue(codecID byte, data []byte) ([]byte, error) {
+	switch codecID {
+	case codecRaw:
+		return data, nil
+	case codecGzip:
+		var buf bytes.Buffer
+		w := gzip.NewWriter(&buf)
+		if _, err := w.Write(data); err != nil {
+			return nil, fmt.Errorf("gzip compress: %w", err)
+		}
+		if err := w.Close(); err != nil {
+			return nil, fmt.Errorf("gzip compress: %w", err)
+		}
+		return buf.Bytes(), nil
+	case codecSnappy:
+		return snappy.Encode(nil, data), nil
+	case codecZstd:
+		enc, err := zstd.NewWriter(nil)
+		if err != nil {
+			return nil, fmt.Errorf("zstd compress: %w", err)
+		}
+		defer enc.Close()
+		return enc.EncodeAll(data, nil), nil
+	default:
+		return nil, fmt.Errorf("unknown codec id %d", codecID)
+	}
+}
+
+// decompressValue reverses compressValue.
+func decompressValue(codecID byte, data []byte) ([]byte, error) {
+	switch codecID {
+	case codecRaw:
+		return data, nil
+	case codecGzip:
+		r, err := gzip.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, fmt.Errorf("gzip decompress: %w", err)
+		}
+		defer r.Close()
+		return io.ReadAll(r)
+	case codecSnappy:
+		return snappy.Decode(nil, data)
+	case codecZstd:
+		dec, err := zstd.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, fmt.Errorf("zstd decompress: %w", err)
+		}
+		defer dec.Close()
+		return io.ReadAll(dec)
+	default:
+		return nil, fmt.Errorf("unknown codec id %d", codecID)
+	}
+}
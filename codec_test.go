@@ -0,0 +1,115 @@
+package main
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPackUnpackValue_RoundTrip(t *testing.T) {
+	value := "the quick brown fox jumps over the lazy dog, repeated for length: the quick brown fox jumps over the lazy dog"
+
+	cases := []struct {
+		name       string
+		encodingID byte
+		codecID    byte
+	}{
+		{"raw/raw", encodingRaw, codecRaw},
+		{"raw/gzip", encodingRaw, codecGzip},
+		{"raw/snappy", encodingRaw, codecSnappy},
+		{"raw/zstd", encodingRaw, codecZstd},
+		{"gob/raw", encodingGob, codecRaw},
+		{"gob/gzip", encodingGob, codecGzip},
+		{"msgpack/raw", encodingMsgpack, codecRaw},
+		{"msgpack/zstd", encodingMsgpack, codecZstd},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			packed, err := packValue(tc.encodingID, tc.codecID, 0, value)
+			require.NoError(t, err)
+
+			unpacked, err := unpackValue(packed)
+			require.NoError(t, err)
+			assert.Equal(t, value, unpacked)
+		})
+	}
+}
+
+func TestPackValue_CompressMinBytesSkipsCompression(t *testing.T) {
+	value := "short"
+
+	packed, err := packValue(encodingRaw, codecGzip, 1024, value)
+	require.NoError(t, err)
+
+	// Below the threshold, the effective codec falls back to raw, so the
+	// value is stored with only the 3-byte header on top.
+	assert.Equal(t, string([]byte{valueMagicByte, encodingRaw, codecRaw})+value, packed)
+}
+
+func TestUnpackValue_LegacyPassthrough(t *testing.T) {
+	legacy := "a plain value written before codec support existed"
+
+	unpacked, err := unpackValue(legacy)
+	require.NoError(t, err)
+	assert.Equal(t, legacy, unpacked)
+}
+
+func TestUnpackValue_ShortInputPassthrough(t *testing.T) {
+	unpacked, err := unpackValue("ab")
+	require.NoError(t, err)
+	assert.Equal(t, "ab", unpacked)
+}
+
+func TestCodecByName_Unknown(t *testing.T) {
+	_, err := codecByName("lz4")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unknown codec")
+}
+
+func TestCodecByName_Default(t *testing.T) {
+	id, err := codecByName("")
+	require.NoError(t, err)
+	assert.Equal(t, codecRaw, id)
+}
+
+func TestEncodingByName_Unknown(t *testing.T) {
+	_, err := encodingByName("protobuf")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unknown encoding")
+}
+
+func TestEncodingByName_Default(t *testing.T) {
+	id, err := encodingByName("")
+	require.NoError(t, err)
+	assert.Equal(t, encodingRaw, id)
+}
+
+func TestExtractPluginParams_StripsKnownKeysAndKeepsOthers(t *testing.T) {
+	q := url.Values{}
+	q.Set("codec", "gzip")
+	q.Set("encoding", "msgpack")
+	q.Set("compressMinBytes", "2048")
+	q.Set("poolSize", "10")
+
+	opts, err := extractPluginParams(q)
+	require.NoError(t, err)
+	assert.Equal(t, codecGzip, opts.codecID)
+	assert.Equal(t, encodingMsgpack, opts.encodingID)
+	assert.Equal(t, 2048, opts.compressMinBytes)
+
+	assert.Empty(t, q.Get("codec"))
+	assert.Empty(t, q.Get("encoding"))
+	assert.Empty(t, q.Get("compressMinBytes"))
+	assert.Equal(t, "10", q.Get("poolSize"))
+}
+
+func TestExtractPluginParams_CompressMinBytesDefault(t *testing.T) {
+	q := url.Values{}
+
+	opts, err := extractPluginParams(q)
+	require.NoError(t, err)
+	assert.Equal(t, defaultCompressMinBytes, opts.compressMinBytes)
+}
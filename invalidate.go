@@ -0,0 +1,94 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// invalidateScanCount is the COUNT hint passed to each SCAN call, bounding
+// how many keys Redis inspects per round trip so a large invalidation
+// doesn't block the server the way a single KEYS call would.
+const invalidateScanCount = 500
+
+// InvalidateByPrefix is equivalent to
+// InvalidateByPrefixContext(context.Background(), pattern). It exists to
+// satisfy the CachePlugin interface, which predates context support; prefer
+// InvalidateByPrefixContext when a caller-scoped context is available.
+func (p *redisCachePlugin) InvalidateByPrefix(pattern string) (int, error) {
+	return p.InvalidateByPrefixContext(context.Background(), pattern)
+}
+
+// InvalidateByPrefixContext deletes every key matching pattern, under this
+// plugin's configured namespace prefix (see redisCachePlugin.prefix), and
+// returns how many keys were removed. It walks the keyspace with SCAN in
+// batches of invalidateScanCount, sending each batch of matched keys to
+// UNLINK (a non-blocking, asynchronous DEL) in a single round trip rather
+// than the blocking KEYS command. This lets EasyREST flush every cache entry
+// for a schema or table, or a whole tenant's namespace, after a mutation
+// without pausing other Redis clients. ctx bounds the call in addition to
+// any ?opTimeout= configured on the plugin.
+//
+// Against a Redis Cluster, matching keys may live on any master shard, so
+// the scan fans out across all of them via ForEachMaster.
+func (p *redisCachePlugin) InvalidateByPrefixContext(ctx context.Context, pattern string) (int, error) {
+	if p.client == nil {
+		return 0, errors.New("redis client not initialized")
+	}
+
+	match := p.prefix + pattern
+	ctx, cancel := p.withOpTimeout(ctx)
+	defer cancel()
+
+	if cluster, ok := p.client.(*redis.ClusterClient); ok {
+		// ForEachMaster calls fn concurrently across shards, so total must be
+		// updated atomically rather than with a plain int captured by the
+		// closure.
+		var total atomic.Int64
+		err := cluster.ForEachMaster(ctx, func(ctx context.Context, shard *redis.Client) error {
+			n, err := scanAndUnlink(ctx, shard, match)
+			total.Add(int64(n))
+			return err
+		})
+		if err != nil {
+			return int(total.Load()), fmt.Errorf("failed to invalidate keys matching %q: %w", pattern, err)
+		}
+		return int(total.Load()), nil
+	}
+
+	total, err := scanAndUnlink(ctx, p.client, match)
+	if err != nil {
+		return total, fmt.Errorf("failed to invalidate keys matching %q: %w", pattern, err)
+	}
+	return total, nil
+}
+
+// scanAndUnlink walks client's keyspace for keys matching match, in batches
+// of invalidateScanCount, UNLINKing each batch as it is found. It returns
+// the total number of keys removed.
+func scanAndUnlink(ctx context.Context, client redis.UniversalClient, match string) (int, error) {
+	var total int
+	var cursor uint64
+	for {
+		keys, next, err := client.Scan(ctx, cursor, match, invalidateScanCount).Result()
+		if err != nil {
+			return total, err
+		}
+
+		if len(keys) > 0 {
+			n, err := client.Unlink(ctx, keys...).Result()
+			if err != nil {
+				return total, err
+			}
+			total += int(n)
+		}
+
+		cursor = next
+		if cursor == 0 {
+			return total, nil
+		}
+	}
+}
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/go-redis/redismock/v9"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInvalidateByPrefix_ClientNil(t *testing.T) {
+	p := &redisCachePlugin{client: nil}
+	n, err := p.InvalidateByPrefix("schema:table:*")
+	require.Error(t, err)
+	assert.Equal(t, "redis client not initialized", err.Error())
+	assert.Zero(t, n)
+}
+
+func TestInvalidateByPrefix_SingleBatch(t *testing.T) {
+	db, mock := redismock.NewClientMock()
+	p := &redisCachePlugin{client: db, prefix: "myapp:"}
+	defer p.Close()
+
+	keys := []string{"myapp:schema:table:1", "myapp:schema:table:2"}
+	mock.ExpectScan(0, "myapp:schema:table:*", invalidateScanCount).SetVal(keys, 0)
+	mock.ExpectUnlink(keys...).SetVal(int64(len(keys)))
+
+	n, err := p.InvalidateByPrefix("schema:table:*")
+	require.NoError(t, err)
+	assert.Equal(t, len(keys), n)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestInvalidateByPrefix_MultipleBatches(t *testing.T) {
+	db, mock := redismock.NewClientMock()
+	p := &redisCachePlugin{client: db}
+	defer p.Close()
+
+	firstBatch := []string{"schema:table:1"}
+	secondBatch := []string{"schema:table:2"}
+
+	mock.ExpectScan(0, "schema:table:*", invalidateScanCount).SetVal(firstBatch, 42)
+	mock.ExpectUnlink(firstBatch...).SetVal(1)
+
+	mock.ExpectScan(42, "schema:table:*", invalidateScanCount).SetVal(secondBatch, 0)
+	mock.ExpectUnlink(secondBatch...).SetVal(1)
+
+	n, err := p.InvalidateByPrefix("schema:table:*")
+	require.NoError(t, err)
+	assert.Equal(t, 2, n)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestInvalidateByPrefix_NoMatches(t *testing.T) {
+	db, mock := redismock.NewClientMock()
+	p := &redisCachePlugin{client: db}
+	defer p.Close()
+
+	mock.ExpectScan(0, "schema:table:*", invalidateScanCount).SetVal(nil, 0)
+
+	n, err := p.InvalidateByPrefix("schema:table:*")
+	require.NoError(t, err)
+	assert.Zero(t, n)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestInvalidateByPrefix_ScanError(t *testing.T) {
+	db, mock := redismock.NewClientMock()
+	p := &redisCachePlugin{client: db}
+	defer p.Close()
+
+	scanErr := errors.New("redis SCAN error")
+	mock.ExpectScan(0, "schema:table:*", invalidateScanCount).SetErr(scanErr)
+
+	n, err := p.InvalidateByPrefix("schema:table:*")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to invalidate keys matching")
+	assert.Zero(t, n)
+}
+
+// TestInvalidateByPrefix_ClusterFanOutIsRaceSafe reproduces, without a
+// *redis.ClusterClient, the concurrent per-shard accumulation that
+// InvalidateByPrefix uses for one: it runs scanAndUnlink against several mock
+// shards from separate goroutines and sums their counts into an atomic
+// total, as the ForEachMaster callback does. Run with -race.
+func TestInvalidateByPrefix_ClusterFanOutIsRaceSafe(t *testing.T) {
+	const shardCount = 8
+
+	var total atomic.Int64
+	var wg sync.WaitGroup
+	errs := make([]error, shardCount)
+
+	for i := 0; i < shardCount; i++ {
+		shard, mock := redismock.NewClientMock()
+		defer shard.Close()
+
+		keys := []string{fmt.Sprintf("myapp:schema:table:%d", i)}
+		mock.ExpectScan(0, "myapp:schema:table:*", invalidateScanCount).SetVal(keys, 0)
+		mock.ExpectUnlink(keys...).SetVal(1)
+
+		wg.Add(1)
+		go func(i int, shard *redis.Client) {
+			defer wg.Done()
+			n, err := scanAndUnlink(context.Background(), shard, "myapp:schema:table:*")
+			total.Add(int64(n))
+			errs[i] = err
+		}(i, shard)
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		require.NoError(t, err)
+	}
+	assert.EqualValues(t, shardCount, total.Load())
+}
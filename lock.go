@@ -0,0 +1,120 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// ErrCacheKeyLocked is returned by GetOrLock when a key is missing from the
+// cache and another worker already holds the computation lock for it. The
+// caller should poll Get, backing off between attempts, until the value
+// appears or the lock's TTL elapses and it can retry GetOrLock itself.
+var ErrCacheKeyLocked = errors.New("easyrest-redis: cache key is locked by another worker")
+
+// unlockScript atomically deletes a lock key only if it still holds the
+// token we set, so a caller can never release a lock it doesn't own
+// (e.g. one that expired and was re-acquired by someone else in the meantime).
+const unlockScript = `
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+else
+	return 0
+end
+`
+
+// lockKey derives the Redis key used to hold the computation lock for a
+// given cache key, kept distinct from the cache entry itself.
+func lockKey(key string) string {
+	return key + ":lock"
+}
+
+// GetOrLock is equivalent to GetOrLockContext(context.Background(), key,
+// lockTTL). It exists to satisfy the CachePlugin interface, which predates
+// context support; prefer GetOrLockContext when a caller-scoped context is
+// available.
+func (p *redisCachePlugin) GetOrLock(key string, lockTTL time.Duration) (value string, lockToken string, err error) {
+	return p.GetOrLockContext(context.Background(), key, lockTTL)
+}
+
+// GetOrLockContext reads key and, on a cache hit, returns its value. On a
+// miss, it attempts to become the single worker responsible for
+// (re)computing the value by atomically acquiring a lock with SET NX PX
+// lockTTL: the caller that wins gets back a lockToken to pass to Unlock once
+// it has written the fresh value; every other concurrent caller gets
+// ErrCacheKeyLocked. This prevents a cache-miss stampede from sending the
+// same expensive computation to every caller at once. ctx bounds the call in
+// addition to any ?opTimeout= configured on the plugin.
+func (p *redisCachePlugin) GetOrLockContext(ctx context.Context, key string, lockTTL time.Duration) (value string, lockToken string, err error) {
+	if p.client == nil {
+		return "", "", errors.New("redis client not initialized")
+	}
+
+	val, err := p.GetContext(ctx, key)
+	if err == nil {
+		return val, "", nil
+	}
+	if !errors.Is(err, redis.Nil) {
+		return "", "", err
+	}
+
+	token, err := generateLockToken()
+	if err != nil {
+		return "", "", fmt.Errorf("failed to generate lock token for key '%s': %w", key, err)
+	}
+
+	ctx, cancel := p.withOpTimeout(ctx)
+	defer cancel()
+	acquired, err := p.client.SetNX(ctx, p.prefix+lockKey(key), token, lockTTL).Result()
+	if err != nil {
+		return "", "", fmt.Errorf("failed to acquire cache lock for key '%s': %w", key, err)
+	}
+	if !acquired {
+		return "", "", ErrCacheKeyLocked
+	}
+
+	return "", token, nil
+}
+
+// Unlock is equivalent to UnlockContext(context.Background(), key, token). It
+// exists to satisfy the CachePlugin interface, which predates context
+// support; prefer UnlockContext when a caller-scoped context is available.
+func (p *redisCachePlugin) Unlock(key string, token string) error {
+	return p.UnlockContext(context.Background(), key, token)
+}
+
+// UnlockContext releases the computation lock acquired by GetOrLock, but only
+// if it is still held by the given token, via a Lua compare-and-delete so a
+// caller never releases a lock someone else has since acquired. ctx bounds
+// the call in addition to any ?opTimeout= configured on the plugin.
+func (p *redisCachePlugin) UnlockContext(ctx context.Context, key string, token string) error {
+	if p.client == nil {
+		return errors.New("redis client not initialized")
+	}
+
+	ctx, cancel := p.withOpTimeout(ctx)
+	defer cancel()
+	res, err := p.client.Eval(ctx, unlockScript, []string{p.prefix + lockKey(key)}, token).Result()
+	if err != nil {
+		return fmt.Errorf("failed to unlock cache key '%s': %w", key, err)
+	}
+	if n, ok := res.(int64); !ok || n == 0 {
+		return fmt.Errorf("failed to unlock cache key '%s': lock not held or already expired", key)
+	}
+	return nil
+}
+
+// generateLockToken returns a random, URL-safe token identifying the holder
+// of a cache lock.
+func generateLockToken() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,135 @@
+package main
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/go-redis/redismock/v9"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetOrLock_ClientNil(t *testing.T) {
+	p := &redisCachePlugin{client: nil}
+	val, token, err := p.GetOrLock("key", time.Second)
+	require.Error(t, err)
+	assert.Equal(t, "redis client not initialized", err.Error())
+	assert.Empty(t, val)
+	assert.Empty(t, token)
+}
+
+func TestGetOrLock_CacheHit(t *testing.T) {
+	db, mock := redismock.NewClientMock()
+	p := &redisCachePlugin{client: db}
+	defer p.Close()
+
+	key := "testkey"
+	expectedValue := "testvalue"
+	packed, err := packValue(p.encodingID, p.codecID, p.compressMinBytes, expectedValue)
+	require.NoError(t, err)
+
+	mock.ExpectGet(key).SetVal(packed)
+
+	val, token, err := p.GetOrLock(key, time.Second)
+	require.NoError(t, err)
+	assert.Equal(t, expectedValue, val)
+	assert.Empty(t, token)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetOrLock_MissAcquiresLock(t *testing.T) {
+	db, mock := redismock.NewClientMock()
+	p := &redisCachePlugin{client: db}
+	defer p.Close()
+
+	key := "testkey"
+	lockTTL := 5 * time.Second
+
+	mock.ExpectGet(key).SetErr(redis.Nil)
+	mock.Regexp().ExpectSetNX(lockKey(key), `^[0-9a-f]{32}$`, lockTTL).SetVal(true)
+
+	val, token, err := p.GetOrLock(key, lockTTL)
+	require.NoError(t, err)
+	assert.Empty(t, val)
+	assert.Len(t, token, 32)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetOrLock_MissAlreadyLocked(t *testing.T) {
+	db, mock := redismock.NewClientMock()
+	p := &redisCachePlugin{client: db}
+	defer p.Close()
+
+	key := "testkey"
+	lockTTL := 5 * time.Second
+
+	mock.ExpectGet(key).SetErr(redis.Nil)
+	mock.Regexp().ExpectSetNX(lockKey(key), `^[0-9a-f]{32}$`, lockTTL).SetVal(false)
+
+	val, token, err := p.GetOrLock(key, lockTTL)
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, ErrCacheKeyLocked))
+	assert.Empty(t, val)
+	assert.Empty(t, token)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetOrLock_RedisErrorOnGet(t *testing.T) {
+	db, mock := redismock.NewClientMock()
+	p := &redisCachePlugin{client: db}
+	defer p.Close()
+
+	key := "testkey"
+	redisErr := errors.New("redis GET error")
+
+	mock.ExpectGet(key).SetErr(redisErr)
+
+	val, token, err := p.GetOrLock(key, time.Second)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to get cache entry")
+	assert.Empty(t, val)
+	assert.Empty(t, token)
+}
+
+func TestUnlock_ClientNil(t *testing.T) {
+	p := &redisCachePlugin{client: nil}
+	err := p.Unlock("key", "token")
+	require.Error(t, err)
+	assert.Equal(t, "redis client not initialized", err.Error())
+}
+
+func TestUnlock_Success(t *testing.T) {
+	db, mock := redismock.NewClientMock()
+	p := &redisCachePlugin{client: db}
+	defer p.Close()
+
+	key := "testkey"
+	token := "sometoken"
+
+	mock.ExpectEval(unlockScript, []string{lockKey(key)}, token).SetVal(int64(1))
+
+	err := p.Unlock(key, token)
+	require.NoError(t, err)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUnlock_NotHeld(t *testing.T) {
+	db, mock := redismock.NewClientMock()
+	p := &redisCachePlugin{client: db}
+	defer p.Close()
+
+	key := "testkey"
+	token := "sometoken"
+
+	mock.ExpectEval(unlockScript, []string{lockKey(key)}, token).SetVal(int64(0))
+
+	err := p.Unlock(key, token)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "lock not held or already expired")
+}
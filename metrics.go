@@ -0,0 +1,170 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/redis/go-redis/v9"
+)
+
+// metricsNamespace prefixes every metric this plugin exports, so they don't
+// collide with metrics from other EasyREST plugins or the host process when
+// scraped into the same registry.
+const metricsNamespace = "easyrest_redis"
+
+// pluginMetrics is the set of Prometheus collectors registered when
+// ?metrics=true is set. opDuration and opResults are fed continuously by
+// metricsHook as commands run; the pool* gauges are only as fresh as the
+// last call to Metrics(), since go-redis exposes pool stats as a point-in-
+// time snapshot rather than pushing updates.
+type pluginMetrics struct {
+	registry *prometheus.Registry
+
+	opDuration *prometheus.HistogramVec
+	opResults  *prometheus.CounterVec
+
+	poolHits       prometheus.Gauge
+	poolMisses     prometheus.Gauge
+	poolTimeouts   prometheus.Gauge
+	poolTotalConns prometheus.Gauge
+	poolIdleConns  prometheus.Gauge
+	poolStaleConns prometheus.Gauge
+}
+
+// newPluginMetrics creates and registers the plugin's collectors in a
+// private registry, so Metrics() reports only this plugin's data.
+func newPluginMetrics() *pluginMetrics {
+	m := &pluginMetrics{
+		registry: prometheus.NewRegistry(),
+		opDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: metricsNamespace,
+			Name:      "operation_duration_seconds",
+			Help:      "Latency of Redis commands issued by this plugin, by command name.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"op"}),
+		opResults: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "operations_total",
+			Help:      "Redis commands issued by this plugin, by command name and result (ok, miss, error).",
+		}, []string{"op", "result"}),
+		poolHits: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: metricsNamespace,
+			Name:      "pool_hits",
+			Help:      "Times a pooled connection was reused without dialing.",
+		}),
+		poolMisses: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: metricsNamespace,
+			Name:      "pool_misses",
+			Help:      "Times a new connection had to be dialed because none were idle.",
+		}),
+		poolTimeouts: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: metricsNamespace,
+			Name:      "pool_timeouts",
+			Help:      "Times a caller gave up waiting for an idle connection.",
+		}),
+		poolTotalConns: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: metricsNamespace,
+			Name:      "pool_total_conns",
+			Help:      "Connections currently open in the pool (active + idle).",
+		}),
+		poolIdleConns: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: metricsNamespace,
+			Name:      "pool_idle_conns",
+			Help:      "Idle connections currently held in the pool.",
+		}),
+		poolStaleConns: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: metricsNamespace,
+			Name:      "pool_stale_conns",
+			Help:      "Stale connections the pool has removed.",
+		}),
+	}
+
+	m.registry.MustRegister(
+		m.opDuration, m.opResults,
+		m.poolHits, m.poolMisses, m.poolTimeouts,
+		m.poolTotalConns, m.poolIdleConns, m.poolStaleConns,
+	)
+	return m
+}
+
+// observe records the outcome of one Redis command for the op latency
+// histogram and result counter.
+func (m *pluginMetrics) observe(op string, err error, d time.Duration) {
+	m.opDuration.WithLabelValues(op).Observe(d.Seconds())
+
+	result := "ok"
+	switch {
+	case errors.Is(err, redis.Nil):
+		result = "miss"
+	case err != nil:
+		result = "error"
+	}
+	m.opResults.WithLabelValues(op, result).Inc()
+}
+
+// recordPoolStats copies a go-redis pool snapshot into the pool gauges.
+func (m *pluginMetrics) recordPoolStats(stats *redis.PoolStats) {
+	m.poolHits.Set(float64(stats.Hits))
+	m.poolMisses.Set(float64(stats.Misses))
+	m.poolTimeouts.Set(float64(stats.Timeouts))
+	m.poolTotalConns.Set(float64(stats.TotalConns))
+	m.poolIdleConns.Set(float64(stats.IdleConns))
+	m.poolStaleConns.Set(float64(stats.StaleConns))
+}
+
+// gather returns the current value of every collector in m's registry.
+func (m *pluginMetrics) gather() ([]*dto.MetricFamily, error) {
+	return m.registry.Gather()
+}
+
+// metricsHook is a redis.Hook that feeds pluginMetrics from every command
+// the hooked client issues, including ones inside a pipeline.
+type metricsHook struct {
+	metrics *pluginMetrics
+}
+
+var _ redis.Hook = (*metricsHook)(nil)
+
+// DialHook leaves dialing untouched; connection setup isn't a per-operation
+// metric this plugin tracks.
+func (h *metricsHook) DialHook(next redis.DialHook) redis.DialHook {
+	return next
+}
+
+func (h *metricsHook) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		start := time.Now()
+		err := next(ctx, cmd)
+		h.metrics.observe(cmd.Name(), err, time.Since(start))
+		return err
+	}
+}
+
+func (h *metricsHook) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return func(ctx context.Context, cmds []redis.Cmder) error {
+		start := time.Now()
+		err := next(ctx, cmds)
+		elapsed := time.Since(start)
+		for _, cmd := range cmds {
+			h.metrics.observe(cmd.Name(), cmd.Err(), elapsed)
+		}
+		return err
+	}
+}
+
+// Metrics returns the plugin's current Prometheus metrics: per-operation
+// latency and result counts plus a fresh snapshot of the connection pool's
+// stats. It returns (nil, nil) if ?metrics=true was not set on the
+// connection URI.
+func (p *redisCachePlugin) Metrics() ([]*dto.MetricFamily, error) {
+	if p.metrics == nil {
+		return nil, nil
+	}
+	if p.client != nil {
+		p.metrics.recordPoolStats(p.client.PoolStats())
+	}
+	return p.metrics.gather()
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/go-redis/redismock/v9"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMetrics_NilWhenDisabled(t *testing.T) {
+	p := &redisCachePlugin{}
+	families, err := p.Metrics()
+	require.NoError(t, err)
+	assert.Nil(t, families)
+}
+
+func TestPluginMetrics_ObserveRecordsResultByOutcome(t *testing.T) {
+	m := newPluginMetrics()
+
+	m.observe("get", nil, 10*time.Millisecond)
+	m.observe("get", redis.Nil, 5*time.Millisecond)
+	m.observe("get", errors.New("boom"), 5*time.Millisecond)
+
+	families, err := m.gather()
+	require.NoError(t, err)
+
+	counts := map[string]float64{}
+	for _, fam := range families {
+		if fam.GetName() != metricsNamespace+"_operations_total" {
+			continue
+		}
+		for _, metric := range fam.GetMetric() {
+			labels := map[string]string{}
+			for _, lp := range metric.GetLabel() {
+				labels[lp.GetName()] = lp.GetValue()
+			}
+			counts[labels["result"]] = metric.GetCounter().GetValue()
+		}
+	}
+
+	assert.Equal(t, 1.0, counts["ok"])
+	assert.Equal(t, 1.0, counts["miss"])
+	assert.Equal(t, 1.0, counts["error"])
+}
+
+func TestMetrics_ReportsPoolStats(t *testing.T) {
+	db, _ := redismock.NewClientMock()
+	metrics := newPluginMetrics()
+	p := &redisCachePlugin{client: db, metrics: metrics}
+	defer p.Close()
+
+	families, err := p.Metrics()
+	require.NoError(t, err)
+
+	var sawPoolStat bool
+	for _, fam := range families {
+		if fam.GetName() == metricsNamespace+"_pool_total_conns" {
+			sawPoolStat = len(fam.GetMetric()) > 0
+		}
+	}
+	assert.True(t, sawPoolStat, "expected pool_total_conns to be reported")
+}
@@ -5,6 +5,8 @@ import (
 	"errors"
 	"flag"
 	"fmt"
+	"net/url"
+	"strconv"
 	"strings"
 	"time"
 
@@ -16,60 +18,350 @@ import (
 // Version can be set during build time
 var Version = "v0.2.0"
 
+// defaultPingTimeout is used as the connection-check timeout for deployment
+// topologies (cluster, sentinel) that do not expose a single combined
+// dial/read timeout the way a plain redis.Options does.
+const defaultPingTimeout = 5 * time.Second
+
 // redisCachePlugin implements the CachePlugin interface using Redis.
 type redisCachePlugin struct {
-	client *redis.Client
+	client redis.UniversalClient
+
+	// encodingID and codecID select the value codec applied by Set/Get; see
+	// codec.go. compressMinBytes is the size, in bytes, below which
+	// compression is skipped even if a compressing codec is configured.
+	encodingID       byte
+	codecID          byte
+	compressMinBytes int
+
+	// prefix is prepended to every key this plugin reads or writes, letting
+	// several EasyREST schemas or tenants share one Redis; see invalidate.go.
+	prefix string
+
+	// cache and cacheSub implement the optional in-process client-side
+	// cache (see clientcache.go); both are nil unless ?clientCache=true.
+	cache    *clientCache
+	cacheSub *redis.PubSub
+
+	// metrics is non-nil when ?metrics=true enabled the Prometheus
+	// collectors fed by metricsHook; see metrics.go.
+	metrics *pluginMetrics
+
+	// tracingEnabled gates the OTel spans startSpan wraps Get/Set/Ping/
+	// Close in; see tracing.go. dbIndex is the logical database selected
+	// (0 unless ?db= was given), reported as a span attribute.
+	tracingEnabled bool
+	dbIndex        int
+
+	// clock is the time source used wherever this plugin needs "now" outside
+	// of Redis itself (currently just clientCache entries); tests substitute
+	// a fake clock to drive TTL expiry deterministically. It defaults to
+	// realClock{} in InitConnection.
+	clock clock
+
+	// opTimeout, if nonzero, bounds every call to Redis via
+	// context.WithTimeout; see withOpTimeout.
+	opTimeout time.Duration
 }
 
 // InitConnection establishes a connection to the Redis server based on the URI.
+// Supported schemes:
+//   - redis:// and rediss://     a single Redis node
+//   - redis+cluster://          a Redis Cluster, e.g. redis+cluster://host1,host2,host3
+//   - redis+sentinel://         a Sentinel-fronted deployment, e.g. redis+sentinel://host1,host2/mymaster
+//
+// In addition to the options understood by the underlying go-redis client,
+// the query string may carry this plugin's own settings (codec, encoding,
+// compressMinBytes, prefix, db, ...); these are stripped before the URI is
+// handed to go-redis so they aren't rejected as unknown options.
 func (p *redisCachePlugin) InitConnection(uri string) error {
-	if !strings.HasPrefix(uri, "redis://") && !strings.HasPrefix(uri, "rediss://") {
-		return errors.New("invalid Redis URI: must start with redis:// or rediss://")
+	u, err := url.Parse(uri)
+	if err != nil {
+		return fmt.Errorf("failed to parse Redis URI: %w", err)
 	}
 
-	// Use redis.ParseURL which handles standard Redis URIs correctly.
-	opts, err := redis.ParseURL(uri)
+	q := u.Query()
+	pluginOpts, err := extractPluginParams(q)
 	if err != nil {
-		return fmt.Errorf("failed to parse Redis URI: %w", err)
+		return err
+	}
+	u.RawQuery = q.Encode()
+	cleanedURI := u.String()
+
+	client, pingTimeout, err := buildClient(u.Scheme, cleanedURI, pluginOpts)
+	if err != nil {
+		return err
+	}
+
+	p.encodingID = pluginOpts.encodingID
+	p.codecID = pluginOpts.codecID
+	p.compressMinBytes = pluginOpts.compressMinBytes
+	p.prefix = pluginOpts.prefix
+	p.dbIndex = pluginOpts.db
+	p.opTimeout = pluginOpts.opTimeout
+	if p.clock == nil {
+		p.clock = realClock{}
+	}
+
+	var cacheSub *redis.PubSub
+	if pluginOpts.clientCache {
+		// Opened before any other call reaches client, so its connection is
+		// the one the OnConnect hook set up by buildClient claims as the
+		// CLIENT TRACKING REDIRECT destination; see clientTrackingOnConnect.
+		cache := newClientCache(pluginOpts.clientCacheSize, pluginOpts.clientCacheTTL, p.clock)
+		cacheSub = startClientCacheSubscriber(context.Background(), client, cache)
+		p.cache = cache
+	}
+
+	if err := p.connect(client, pingTimeout); err != nil {
+		if cacheSub != nil {
+			cacheSub.Close()
+			p.cache = nil
+		}
+		return err
+	}
+	p.cacheSub = cacheSub
+
+	if pluginOpts.metrics {
+		p.metrics = newPluginMetrics()
+		p.client.AddHook(&metricsHook{metrics: p.metrics})
+	}
+	p.tracingEnabled = pluginOpts.tracing
+
+	return nil
+}
+
+// withOpTimeout bounds ctx by p.opTimeout, if one was configured via
+// ?opTimeout=, so a slow Redis server fails the calling request instead of
+// letting it block indefinitely on the connection pool. The returned cancel
+// func is always safe to defer, even when no timeout applies.
+func (p *redisCachePlugin) withOpTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if p.opTimeout <= 0 {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ctx, p.opTimeout)
+}
+
+// buildClient constructs the redis.UniversalClient appropriate for scheme
+// from the (already cleaned of plugin-specific params) URI, along with a
+// reasonable timeout for the initial connectivity ping.
+func buildClient(scheme, cleanedURI string, pluginOpts pluginQueryOptions) (redis.UniversalClient, time.Duration, error) {
+	// Shared by every connection the returned client opens, so they all agree
+	// on which one is the CLIENT TRACKING REDIRECT destination; see
+	// clientTrackingOnConnect.
+	redirectTarget := &trackingRedirectTarget{}
+
+	switch scheme {
+	case "redis+cluster":
+		if pluginOpts.dbSet && pluginOpts.db != 0 {
+			return nil, 0, errors.New("invalid Redis URI: redis+cluster:// does not support selecting a database other than 0")
+		}
+		client, err := newClusterClient(cleanedURI, pluginOpts, redirectTarget)
+		if err != nil {
+			return nil, 0, err
+		}
+		return client, defaultPingTimeout, nil
+	case "redis+sentinel":
+		client, err := newSentinelClient(cleanedURI, pluginOpts, redirectTarget)
+		if err != nil {
+			return nil, 0, err
+		}
+		return client, defaultPingTimeout, nil
+	case "redis", "rediss":
+		// Use redis.ParseURL which handles standard Redis URIs correctly.
+		opts, err := redis.ParseURL(cleanedURI)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to parse Redis URI: %w", err)
+		}
+		if pluginOpts.dbSet {
+			opts.DB = pluginOpts.db
+		}
+		if pluginOpts.clientCache {
+			opts.OnConnect = clientTrackingOnConnect(pluginOpts.prefix, redirectTarget)
+		}
+		// Use a reasonable combined timeout for the initial ping.
+		return redis.NewClient(opts), opts.DialTimeout + opts.ReadTimeout + 1*time.Second, nil
+	default:
+		return nil, 0, errors.New("invalid Redis URI: must start with redis://, rediss://, redis+cluster://, or redis+sentinel://")
+	}
+}
+
+// newClusterClient builds a redis.ClusterClient from a redis+cluster:// URI.
+// The host portion is a comma-separated list of cluster node addresses; query
+// parameters map onto the corresponding redis.ClusterOptions fields.
+func newClusterClient(uri string, pluginOpts pluginQueryOptions, redirectTarget *trackingRedirectTarget) (*redis.ClusterClient, error) {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse Redis URI: %w", err)
+	}
+	if u.Host == "" {
+		return nil, errors.New("invalid Redis URI: redis+cluster:// requires at least one host, e.g. redis+cluster://host1,host2")
+	}
+
+	opts := &redis.ClusterOptions{
+		Addrs: strings.Split(u.Host, ","),
+	}
+	if u.User != nil {
+		opts.Username = u.User.Username()
+		opts.Password, _ = u.User.Password()
+	}
+
+	q := u.Query()
+	if opts.RouteByLatency, err = parseBoolParam(q, "routeByLatency"); err != nil {
+		return nil, err
+	}
+	if opts.RouteRandomly, err = parseBoolParam(q, "routeRandomly"); err != nil {
+		return nil, err
+	}
+	if opts.ReadOnly, err = parseBoolParam(q, "readOnly"); err != nil {
+		return nil, err
+	}
+	if pluginOpts.clientCache {
+		opts.OnConnect = clientTrackingOnConnect(pluginOpts.prefix, redirectTarget)
+	}
+
+	return redis.NewClusterClient(opts), nil
+}
+
+// newSentinelClient builds a redis.Client backed by Sentinel from a
+// redis+sentinel:// URI. The host portion is a comma-separated list of
+// sentinel addresses and the path holds the monitored master name.
+func newSentinelClient(uri string, pluginOpts pluginQueryOptions, redirectTarget *trackingRedirectTarget) (*redis.Client, error) {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse Redis URI: %w", err)
+	}
+	if u.Host == "" {
+		return nil, errors.New("invalid Redis URI: redis+sentinel:// requires at least one sentinel host, e.g. redis+sentinel://host1,host2/mymaster")
 	}
 
-	p.client = redis.NewClient(opts)
+	masterName := strings.Trim(u.Path, "/")
+	if masterName == "" {
+		return nil, errors.New("invalid Redis URI: redis+sentinel:// requires a master name in the path, e.g. redis+sentinel://host1,host2/mymaster")
+	}
+
+	opts := &redis.FailoverOptions{
+		MasterName:    masterName,
+		SentinelAddrs: strings.Split(u.Host, ","),
+	}
+	if u.User != nil {
+		opts.Username = u.User.Username()
+		opts.Password, _ = u.User.Password()
+	}
 
-	// Ping the server to ensure connection is working.
-	ctx, cancel := context.WithTimeout(context.Background(), opts.DialTimeout+opts.ReadTimeout+1*time.Second) // Use a reasonable combined timeout
+	q := u.Query()
+	opts.SentinelUsername = q.Get("sentinelUsername")
+	opts.SentinelPassword = q.Get("sentinelPassword")
+	if opts.RouteByLatency, err = parseBoolParam(q, "routeByLatency"); err != nil {
+		return nil, err
+	}
+	if opts.RouteRandomly, err = parseBoolParam(q, "routeRandomly"); err != nil {
+		return nil, err
+	}
+	if opts.ReplicaOnly, err = parseBoolParam(q, "replicaOnly"); err != nil {
+		return nil, err
+	}
+	if pluginOpts.dbSet {
+		opts.DB = pluginOpts.db
+	}
+	if pluginOpts.clientCache {
+		opts.OnConnect = clientTrackingOnConnect(pluginOpts.prefix, redirectTarget)
+	}
+
+	return redis.NewFailoverClient(opts), nil
+}
+
+// parseBoolParam returns the parsed boolean value of the named query
+// parameter, or false if it is absent.
+func parseBoolParam(q url.Values, name string) (bool, error) {
+	v := q.Get(name)
+	if v == "" {
+		return false, nil
+	}
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		return false, fmt.Errorf("invalid %s value %q: %w", name, v, err)
+	}
+	return b, nil
+}
+
+// connect pings the given client to verify connectivity and, on success,
+// stores it on the plugin.
+func (p *redisCachePlugin) connect(client redis.UniversalClient, pingTimeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(context.Background(), pingTimeout)
 	defer cancel()
 
-	status := p.client.Ping(ctx)
-	if err := status.Err(); err != nil {
-		p.client.Close() // Close the client if ping fails
-		p.client = nil
+	if err := client.Ping(ctx).Err(); err != nil {
+		client.Close() // Close the client if ping fails
 		return fmt.Errorf("failed to ping Redis server: %w", err)
 	}
 
+	p.client = client
 	return nil
 }
 
-// Set stores a key-value pair with a TTL in the Redis cache.
+// Set is equivalent to SetContext(context.Background(), key, value, ttl). It
+// exists to satisfy the CachePlugin interface, which predates context
+// support; prefer SetContext when a caller-scoped context is available.
 func (p *redisCachePlugin) Set(key string, value string, ttl time.Duration) error {
+	return p.SetContext(context.Background(), key, value, ttl)
+}
+
+// SetContext stores a key-value pair with a TTL in the Redis cache. The value
+// is framed by the configured codec (see codec.go) before being written. ctx
+// bounds the call in addition to any ?opTimeout= configured on the plugin.
+func (p *redisCachePlugin) SetContext(ctx context.Context, key string, value string, ttl time.Duration) error {
 	if p.client == nil {
 		return errors.New("redis client not initialized")
 	}
-	ctx := context.Background() // Use background context for cache operations
-	err := p.client.Set(ctx, key, value, ttl).Err()
+	packed, err := packValue(p.encodingID, p.codecID, p.compressMinBytes, value)
+	if err != nil {
+		return fmt.Errorf("failed to encode cache entry for key '%s': %w", key, err)
+	}
+	fullKey := p.prefix + key
+	ctx, cancel := p.withOpTimeout(ctx)
+	defer cancel()
+	ctx, end := p.startSpan(ctx, "set", fullKey, ttl)
+	err = p.client.Set(ctx, fullKey, packed, ttl).Err()
+	end(err)
 	if err != nil {
 		return fmt.Errorf("failed to set cache entry in Redis for key '%s': %w", key, err)
 	}
+	if p.cache != nil {
+		// Refresh our own copy immediately rather than waiting for the
+		// invalidation push, which could otherwise serve a stale read
+		// between our own Set and its arrival.
+		p.cache.set(fullKey, value)
+	}
 	return nil
 }
 
-// Get retrieves a value from the Redis cache.
-// It returns redis.Nil if the key is not found.
+// Get is equivalent to GetContext(context.Background(), key). It exists to
+// satisfy the CachePlugin interface, which predates context support; prefer
+// GetContext when a caller-scoped context is available.
 func (p *redisCachePlugin) Get(key string) (string, error) {
+	return p.GetContext(context.Background(), key)
+}
+
+// GetContext retrieves a value from the Redis cache, reversing whatever
+// codec it was stored with (legacy unframed values are returned unchanged).
+// It returns redis.Nil if the key is not found. ctx bounds the call in
+// addition to any ?opTimeout= configured on the plugin.
+func (p *redisCachePlugin) GetContext(ctx context.Context, key string) (string, error) {
 	if p.client == nil {
 		return "", errors.New("redis client not initialized")
 	}
-	ctx := context.Background()
-	val, err := p.client.Get(ctx, key).Result()
+	fullKey := p.prefix + key
+	if p.cache != nil {
+		if val, ok := p.cache.get(fullKey); ok {
+			return val, nil
+		}
+	}
+	ctx, cancel := p.withOpTimeout(ctx)
+	defer cancel()
+	ctx, end := p.startSpan(ctx, "get", fullKey, 0)
+	val, err := p.client.Get(ctx, fullKey).Result()
+	end(err)
 	if err != nil {
 		if errors.Is(err, redis.Nil) {
 			// Return redis.Nil directly as go-redis v9 recommends.
@@ -78,11 +370,54 @@ func (p *redisCachePlugin) Get(key string) (string, error) {
 		}
 		return "", fmt.Errorf("failed to get cache entry from Redis for key '%s': %w", key, err)
 	}
-	return val, nil
+	unpacked, err := unpackValue(val)
+	if err != nil {
+		return "", fmt.Errorf("failed to decode cache entry from Redis for key '%s': %w", key, err)
+	}
+	if p.cache != nil {
+		p.cache.set(fullKey, unpacked)
+	}
+	return unpacked, nil
+}
+
+// Ping checks connectivity to the Redis server. It is a lightweight health
+// check independent of Get/Set, primarily useful as its own traced
+// operation when ?tracing=true is set.
+func (p *redisCachePlugin) Ping() error {
+	if p.client == nil {
+		return errors.New("redis client not initialized")
+	}
+	ctx, cancel := p.withOpTimeout(context.Background())
+	defer cancel()
+	ctx, end := p.startSpan(ctx, "ping", "", 0)
+	err := p.client.Ping(ctx).Err()
+	end(err)
+	if err != nil {
+		return fmt.Errorf("failed to ping Redis server: %w", err)
+	}
+	return nil
 }
 
-// Close cleans up the Redis client connection.
+// Close is equivalent to CloseContext(context.Background()). It exists to
+// satisfy the CachePlugin interface, which predates context support; prefer
+// CloseContext when a caller-scoped context is available.
 func (p *redisCachePlugin) Close() error {
+	return p.CloseContext(context.Background())
+}
+
+// CloseContext cleans up the Redis client connection. ctx carries only the
+// close span; closing the pub/sub connection and the client takes no
+// context, so neither ctx nor ?opTimeout= bounds the shutdown itself.
+func (p *redisCachePlugin) CloseContext(ctx context.Context) error {
+	ctx, cancel := p.withOpTimeout(ctx)
+	defer cancel()
+	_, end := p.startSpan(ctx, "close", "", 0)
+	defer end(nil)
+
+	if p.cacheSub != nil {
+		p.cacheSub.Close()
+		p.cacheSub = nil
+	}
 	if p.client != nil {
 		err := p.client.Close()
 		p.client = nil // Ensure client is marked as nil after closing
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"errors"
 	"testing"
 	"time"
@@ -60,6 +61,126 @@ func TestInitConnection_ParseError(t *testing.T) {
 
 }
 
+func TestInitConnection_ClusterURI_NoHost(t *testing.T) {
+	p := &redisCachePlugin{}
+	err := p.InitConnection("redis+cluster:///")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "requires at least one host")
+	assert.Nil(t, p.client)
+}
+
+func TestInitConnection_ClusterURI_InvalidBoolParam(t *testing.T) {
+	p := &redisCachePlugin{}
+	err := p.InitConnection("redis+cluster://host1,host2?routeByLatency=maybe")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid routeByLatency value")
+	assert.Nil(t, p.client)
+}
+
+func TestInitConnection_SentinelURI_NoHost(t *testing.T) {
+	p := &redisCachePlugin{}
+	err := p.InitConnection("redis+sentinel:///mymaster")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "requires at least one sentinel host")
+	assert.Nil(t, p.client)
+}
+
+func TestInitConnection_SentinelURI_MissingMasterName(t *testing.T) {
+	p := &redisCachePlugin{}
+	err := p.InitConnection("redis+sentinel://host1,host2")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "requires a master name in the path")
+	assert.Nil(t, p.client)
+}
+
+func TestInitConnection_SentinelURI_InvalidDbParam(t *testing.T) {
+	p := &redisCachePlugin{}
+	err := p.InitConnection("redis+sentinel://host1,host2/mymaster?db=notanumber")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid db value")
+	assert.Nil(t, p.client)
+}
+
+func TestInitConnection_InvalidCodecParam(t *testing.T) {
+	p := &redisCachePlugin{}
+	err := p.InitConnection("redis://localhost:6379?codec=lz4")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unknown codec")
+	assert.Nil(t, p.client)
+}
+
+func TestInitConnection_InvalidEncodingParam(t *testing.T) {
+	p := &redisCachePlugin{}
+	err := p.InitConnection("redis://localhost:6379?encoding=protobuf")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unknown encoding")
+	assert.Nil(t, p.client)
+}
+
+func TestInitConnection_InvalidCompressMinBytesParam(t *testing.T) {
+	p := &redisCachePlugin{}
+	err := p.InitConnection("redis://localhost:6379?compressMinBytes=notanumber")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid compressMinBytes value")
+	assert.Nil(t, p.client)
+}
+
+func TestInitConnection_InvalidClientCacheParam(t *testing.T) {
+	p := &redisCachePlugin{}
+	err := p.InitConnection("redis://localhost:6379?clientCache=maybe")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid clientCache value")
+	assert.Nil(t, p.client)
+}
+
+func TestInitConnection_InvalidClientCacheSizeParam(t *testing.T) {
+	p := &redisCachePlugin{}
+	err := p.InitConnection("redis://localhost:6379?clientCache=true&clientCacheSize=notanumber")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid clientCacheSize value")
+	assert.Nil(t, p.client)
+}
+
+func TestInitConnection_InvalidClientCacheTTLParam(t *testing.T) {
+	p := &redisCachePlugin{}
+	err := p.InitConnection("redis://localhost:6379?clientCache=true&clientCacheTTL=notaduration")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid clientCacheTTL value")
+	assert.Nil(t, p.client)
+}
+
+func TestInitConnection_InvalidMetricsParam(t *testing.T) {
+	p := &redisCachePlugin{}
+	err := p.InitConnection("redis://localhost:6379?metrics=maybe")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid metrics value")
+	assert.Nil(t, p.client)
+}
+
+func TestInitConnection_InvalidTracingParam(t *testing.T) {
+	p := &redisCachePlugin{}
+	err := p.InitConnection("redis://localhost:6379?tracing=maybe")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid tracing value")
+	assert.Nil(t, p.client)
+}
+
+func TestInitConnection_InvalidOpTimeoutParam(t *testing.T) {
+	p := &redisCachePlugin{}
+	err := p.InitConnection("redis://localhost:6379?opTimeout=notaduration")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid opTimeout value")
+	assert.Nil(t, p.client)
+}
+
+func TestInitConnection_UnknownScheme(t *testing.T) {
+	p := &redisCachePlugin{}
+	err := p.InitConnection("memcached://localhost:11211")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid Redis URI")
+	assert.Nil(t, p.client)
+}
+
 func TestSet_ClientNil(t *testing.T) {
 	p := &redisCachePlugin{client: nil} // Explicitly nil
 	err := p.Set("key", "value", 1*time.Minute)
@@ -109,9 +230,12 @@ func TestSet_Success(t *testing.T) {
 	value := "testvalue"
 	ttl := 1 * time.Minute
 
-	mock.ExpectSet(key, value, ttl).SetVal("OK")
+	packed, err := packValue(p.encodingID, p.codecID, p.compressMinBytes, value)
+	require.NoError(t, err)
 
-	err := p.Set(key, value, ttl)
+	mock.ExpectSet(key, packed, ttl).SetVal("OK")
+
+	err = p.Set(key, value, ttl)
 	require.NoError(t, err)
 
 	assert.NoError(t, mock.ExpectationsWereMet())
@@ -180,6 +304,75 @@ func TestGet_Success(t *testing.T) {
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
 
+func TestWithOpTimeout_NoTimeoutConfigured(t *testing.T) {
+	p := &redisCachePlugin{}
+
+	ctx, cancel := p.withOpTimeout(context.Background())
+	defer cancel()
+
+	_, hasDeadline := ctx.Deadline()
+	assert.False(t, hasDeadline)
+}
+
+func TestWithOpTimeout_AppliesConfiguredTimeout(t *testing.T) {
+	p := &redisCachePlugin{opTimeout: time.Minute}
+
+	ctx, cancel := p.withOpTimeout(context.Background())
+	defer cancel()
+
+	deadline, ok := ctx.Deadline()
+	require.True(t, ok)
+	assert.WithinDuration(t, time.Now().Add(time.Minute), deadline, 5*time.Second)
+}
+
+func TestGet_ClientCacheHitAvoidsRedis(t *testing.T) {
+	db, mock := redismock.NewClientMock()
+	p := &redisCachePlugin{client: db, cache: newClientCache(10, time.Minute, realClock{})}
+	defer p.Close()
+
+	key := "testkey"
+	expectedValue := "testvalue"
+
+	mock.ExpectGet(key).SetVal(expectedValue)
+
+	val, err := p.Get(key)
+	require.NoError(t, err)
+	assert.Equal(t, expectedValue, val)
+
+	// Second call should be served from the in-process cache, so no
+	// additional GET is expected of the mock.
+	val, err = p.Get(key)
+	require.NoError(t, err)
+	assert.Equal(t, expectedValue, val)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSet_RefreshesClientCache(t *testing.T) {
+	db, mock := redismock.NewClientMock()
+	p := &redisCachePlugin{client: db, cache: newClientCache(10, time.Minute, realClock{})}
+	defer p.Close()
+
+	key := "testkey"
+	value := "testvalue"
+	ttl := time.Minute
+
+	packed, err := packValue(p.encodingID, p.codecID, p.compressMinBytes, value)
+	require.NoError(t, err)
+	mock.ExpectSet(key, packed, ttl).SetVal("OK")
+
+	err = p.Set(key, value, ttl)
+	require.NoError(t, err)
+
+	// A subsequent Get should come from the cache entry Set just wrote,
+	// without issuing another GET.
+	val, err := p.Get(key)
+	require.NoError(t, err)
+	assert.Equal(t, value, val)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
 func TestClose_ClientNil(t *testing.T) {
 	p := &redisCachePlugin{client: nil}
 	err := p.Close()
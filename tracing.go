@@ -0,0 +1,52 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracerName identifies the spans this plugin creates to whatever OTel
+// TracerProvider the host process has registered globally.
+const tracerName = "github.com/onegreyonewhite/easyrest-plugin-redis"
+
+var tracer = otel.Tracer(tracerName)
+
+// startSpan starts a span for a cache operation named op when tracing is
+// enabled (?tracing=true), attaching the attributes EasyREST's other
+// storage plugins use for Redis spans: the database system, its logical
+// index, and whichever of key/ttl apply to the operation. It returns the
+// (possibly span-carrying) context to use for the Redis call and a func to
+// end the span with the call's outcome; both are no-ops when tracing is
+// disabled, so callers can use them unconditionally.
+func (p *redisCachePlugin) startSpan(ctx context.Context, op, key string, ttl time.Duration) (context.Context, func(err error)) {
+	if !p.tracingEnabled {
+		return ctx, func(error) {}
+	}
+
+	attrs := []attribute.KeyValue{
+		attribute.String("db.system", "redis"),
+		attribute.Int("db.redis.database_index", p.dbIndex),
+	}
+	if key != "" {
+		attrs = append(attrs, attribute.Int("db.redis.key_length", len(key)))
+	}
+	if ttl > 0 {
+		attrs = append(attrs, attribute.Int64("db.redis.ttl_ms", ttl.Milliseconds()))
+	}
+
+	ctx, span := tracer.Start(ctx, "redis."+op, trace.WithAttributes(attrs...))
+	return ctx, func(err error) {
+		if err != nil && !errors.Is(err, redis.Nil) {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+	}
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestStartSpan_DisabledIsNoop(t *testing.T) {
+	p := &redisCachePlugin{tracingEnabled: false}
+
+	ctx, end := p.startSpan(context.Background(), "get", "key", 0)
+	assert.NotNil(t, ctx)
+	end(errors.New("boom")) // must not panic with no tracer configured
+}
+
+func TestStartSpan_RecordsAttributesAndErrors(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	prevTracer := tracer
+	tracerProvider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	tracer = tracerProvider.Tracer(tracerName)
+	defer func() { tracer = prevTracer }()
+
+	p := &redisCachePlugin{tracingEnabled: true, dbIndex: 2}
+
+	_, end := p.startSpan(context.Background(), "set", "myapp:key", 5*time.Second)
+	end(errors.New("write failed"))
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 1)
+
+	span := spans[0]
+	assert.Equal(t, "redis.set", span.Name())
+
+	attrs := map[string]bool{}
+	for _, kv := range span.Attributes() {
+		attrs[string(kv.Key)] = true
+	}
+	assert.True(t, attrs["db.system"])
+	assert.True(t, attrs["db.redis.database_index"])
+	assert.True(t, attrs["db.redis.key_length"])
+	assert.True(t, attrs["db.redis.ttl_ms"])
+
+	require.Len(t, span.Events(), 1)
+	assert.Equal(t, "exception", span.Events()[0].Name)
+}